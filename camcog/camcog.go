@@ -0,0 +1,373 @@
+// Package camcog is a client for the CAMCOG URL service.
+//
+// It generates a unique URL for a set of cognitive questionnaires using the CAMCOG web service.
+//
+// See API documentation at https://cantab.atlassian.net/wiki/spaces/API/overview
+// and https://cantab.atlassian.net/wiki/spaces/API/pages/137987972/Generating+a+Subject+URL
+//
+// It is designed to be used by a command-line tool taking in a single subject identifier
+// or processing a list of identifiers from a CSV file. It can also be used to
+// create a custom web service that simply redirects to the questionnaire.
+package camcog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the default CANTAB Connect demo endpoint.
+	DefaultBaseURL = "connect_demo.int.cantab.com"
+	// DefaultUserAgent is sent in the User-Agent header if none is configured.
+	DefaultUserAgent = "eldrix-camcog/1"
+)
+
+// StatusError represents an error on the service-side
+type StatusError struct {
+	Code int
+	Err  error
+}
+
+func checkStatusError(res *http.Response) error {
+	if res.StatusCode < 300 {
+		return nil
+	}
+	return &StatusError{
+		Code: res.StatusCode,
+		Err:  fmt.Errorf("error %s", res.Status),
+	}
+}
+
+func (se StatusError) Error() string {
+	return se.Err.Error()
+}
+
+// Camcog encapsulates the remote REST camcog service
+type Camcog struct {
+	baseURL     *url.URL
+	httpClient  *http.Client
+	username    string
+	password    string
+	userAgent   string
+	timeout     time.Duration
+	logger      Logger
+	retryPolicy RetryPolicy
+}
+
+// Option configures optional behaviour of a Camcog client, set at construction via
+// NewCamcog or NewCamcogWithOptions.
+type Option func(*Camcog)
+
+// WithHTTPClient sets the *http.Client used to make requests, instead of http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(cc *Camcog) { cc.httpClient = client }
+}
+
+// WithTransport sets the http.RoundTripper used by the client's *http.Client, leaving its
+// other settings (such as Timeout) untouched.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(cc *Camcog) {
+		client := *cc.httpClient
+		client.Transport = rt
+		cc.httpClient = &client
+	}
+}
+
+// WithTimeout bounds every request made by the client to d, regardless of any deadline
+// already present on the context passed in by the caller. A zero value leaves requests
+// bounded only by the caller's context.
+func WithTimeout(d time.Duration) Option {
+	return func(cc *Camcog) { cc.timeout = d }
+}
+
+// WithBasicAuth sets the HTTP basic-auth credentials sent with every request. It is the
+// only way to supply credentials when using NewCamcogWithOptions.
+func WithBasicAuth(username, password string) Option {
+	return func(cc *Camcog) {
+		cc.username = username
+		cc.password = password
+	}
+}
+
+// WithUserAgent overrides the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(cc *Camcog) { cc.userAgent = userAgent }
+}
+
+// WithLogger sets a logger used to report retried requests. No logging is performed
+// if no logger is configured.
+func WithLogger(l Logger) Option {
+	return func(cc *Camcog) { cc.logger = l }
+}
+
+// WithRetryPolicy sets a bounded exponential-backoff retry policy applied to failed
+// requests. See RetryPolicy for the rules governing which requests are retried.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(cc *Camcog) { cc.retryPolicy = p }
+}
+
+// NewCamcog creates a new service client using the specified configuration.
+func NewCamcog(baseURL string, username string, password string, userAgent string, opts ...Option) (*Camcog, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	cc := &Camcog{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		username:   username,
+		password:   password,
+		userAgent:  userAgent,
+	}
+	for _, opt := range opts {
+		opt(cc)
+	}
+	return cc, nil
+}
+
+// NewCamcogWithOptions creates a new service client configured entirely via Option values,
+// following the request-option pattern used by many modern API client SDKs. Use
+// WithBasicAuth to supply credentials, as none are taken positionally.
+func NewCamcogWithOptions(baseURL string, opts ...Option) (*Camcog, error) {
+	return NewCamcog(baseURL, "", "", "", opts...)
+}
+
+// withTimeout derives a context bounded by the per-call timeout in rc, falling back to
+// cc.timeout if rc has none configured. The returned cancel function must always be
+// called by the caller once the request it guards has completed.
+func (cc *Camcog) withTimeout(ctx context.Context, rc *requestConfig) (context.Context, context.CancelFunc) {
+	timeout := cc.timeout
+	if rc.timeout > 0 {
+		timeout = rc.timeout
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// ErrSubjectNotFound is returned by LookupSubject when no subject is already registered
+// under the given identifier.
+var ErrSubjectNotFound = errors.New("camcog: subject not found")
+
+// GetSubject either fetches an existing subject or registers a new one using the subject identifier specified.
+// Options supplied apply only to the subject-creation request; the lookup is a GET and is
+// always safe to retry.
+func (cc Camcog) GetSubject(ctx context.Context, groupDef string, org string, studyID string, site string, studyDef string, subjectID string, opts ...RequestOption) (*SubjectsResponse, error) {
+	sr, err := cc.getSubject(ctx, studyID, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	if len(sr.Records) == 0 {
+		sr, err = cc.createSubject(ctx, groupDef, org, studyID, site, studyDef, subjectID, opts...)
+	}
+	return sr, err
+}
+
+// LookupSubject fetches an already-registered subject's record, returning ErrSubjectNotFound
+// if no such subject exists. Unlike GetSubject, it never registers a new subject, so it is
+// safe to expose to callers that must not be able to create data in the remote study, such
+// as an unauthenticated lookup service.
+func (cc Camcog) LookupSubject(ctx context.Context, studyID string, subjectID string) (*SubjectsResponse, error) {
+	sr, err := cc.getSubject(ctx, studyID, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	if len(sr.Records) == 0 {
+		return nil, ErrSubjectNotFound
+	}
+	return sr, nil
+}
+
+// getSubject returns a subject, or an empty response if that subject is not already registered.
+func (cc Camcog) getSubject(ctx context.Context, studyID string, subjectID string) (*SubjectsResponse, error) {
+	ctx, cancel := cc.withTimeout(ctx, newRequestConfig(nil))
+	defer cancel()
+	params := make(map[string]string)
+	params["limit"] = "1"
+	params["filter"] = fmt.Sprintf("{\"study\":\"%s\",subjectIds=\"%s\"}", studyID, subjectID)
+	req, err := cc.newRequest(ctx, "GET", "/api/subject", nil, params)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(cc.username, cc.password)
+	var sres SubjectsResponse
+	res, err := cc.do(req, &sres)
+	if err != nil {
+		return nil, err
+	}
+	return &sres, checkStatusError(res)
+}
+
+// createSubject creates a new subject, failing if that subject is already registered.
+// Pass WithIdempotencyKey to make the POST safe to retry after a network blip, without
+// risking the creation of a duplicate subject.
+func (cc Camcog) createSubject(ctx context.Context, groupDef string, org string, studyID string, site string, studyDef string, subjectID string, opts ...RequestOption) (*SubjectsResponse, error) {
+	rc := newRequestConfig(opts)
+	ctx, cancel := cc.withTimeout(ctx, rc)
+	defer cancel()
+	csr := &CreateSubjectRequest{
+		GroupDef:     groupDef,
+		Organisation: org,
+		Site:         site,
+		Status:       "NEW",
+		Study:        studyID,
+		StudyDef:     studyDef,
+		SubjectIds:   []string{subjectID},
+	}
+	req, err := cc.newRequest(ctx, "POST", "/server-webservices/subject", csr, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(cc.username, cc.password)
+	rc.apply(req)
+	var sres SubjectsResponse
+	res, err := cc.do(req, &sres)
+	if err != nil {
+		return nil, err
+	}
+	return &sres, checkStatusError(res)
+}
+
+// GenerateURL generates a URL for the subject to complete their questionnaires
+func (cc Camcog) GenerateURL(subject string, accesscode string) string {
+	return fmt.Sprintf("https://app.cantab.com/subject/index.html?accessCode=%s&subject=%s", accesscode, subject)
+}
+
+// GenerateSubjectAccessCode generates an access code for the subject specified. Pass
+// WithIdempotencyKey so that a retried request cannot result in two access codes being
+// generated for the same subject.
+func (cc Camcog) GenerateSubjectAccessCode(ctx context.Context, subjectUUID string, opts ...RequestOption) (*SubjectLoginInfo, error) {
+	rc := newRequestConfig(opts)
+	ctx, cancel := cc.withTimeout(ctx, rc)
+	defer cancel()
+	params := make(map[string]string)
+	params["limit"] = "1"
+	params["filter"] = fmt.Sprintf("{\"subject\":\"%s\"}", subjectUUID)
+	req, err := cc.newRequest(ctx, "GET", "/server-webservices/subjectLoginInfo", nil, params)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(cc.username, cc.password)
+	rc.apply(req)
+	var sli SubjectLoginInfo
+	res, err := cc.do(req, &sli)
+	if err != nil {
+		return nil, err
+	}
+	return &sli, checkStatusError(res)
+}
+
+// GetStudyDefinition fetches the study definition for studyID, describing the valid
+// groupDef, site, studyDef, and subjectIdentifierDefs values accepted by the remote
+// CAMCOG server for that study.
+func (cc Camcog) GetStudyDefinition(ctx context.Context, studyID string) (*StudyDefinitionResponse, error) {
+	ctx, cancel := cc.withTimeout(ctx, newRequestConfig(nil))
+	defer cancel()
+	params := make(map[string]string)
+	params["limit"] = "1"
+	params["filter"] = fmt.Sprintf("{\"study\":\"%s\"}", studyID)
+	req, err := cc.newRequest(ctx, "GET", "/api/studyDef", nil, params)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(cc.username, cc.password)
+	var sdr StudyDefinitionResponse
+	res, err := cc.do(req, &sdr)
+	if err != nil {
+		return nil, err
+	}
+	return &sdr, checkStatusError(res)
+}
+
+func (cc *Camcog) newRequest(ctx context.Context, method, path string, body interface{}, params map[string]string) (*http.Request, error) {
+	rel := &url.URL{Path: path}
+	u := cc.baseURL.ResolveReference(rel)
+	if len(params) > 0 {
+		q := u.Query()
+		for k, v := range params {
+			q.Add(k, v)
+		}
+		u.RawQuery = q.Encode()
+	}
+	var buf io.ReadWriter
+	if body != nil {
+		buf = new(bytes.Buffer)
+		err := json.NewEncoder(buf).Encode(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), buf)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", cc.userAgent)
+	return req, nil
+}
+
+// do performs req, decoding a successful JSON response into v. GET requests, and any
+// request carrying an idempotency key, are retried according to cc.retryPolicy on a
+// 429 or 5xx response (honouring Retry-After) or a network error.
+func (cc *Camcog) do(req *http.Request, v interface{}) (*http.Response, error) {
+	retryable := req.Method == http.MethodGet || req.Header.Get(idempotencyKeyHeader) != ""
+	if !retryable || !cc.retryPolicy.enabled() {
+		return cc.doOnce(req, v)
+	}
+	var res *http.Response
+	var err error
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if req.GetBody != nil {
+				body, berr := req.GetBody()
+				if berr != nil {
+					return res, berr
+				}
+				req.Body = body
+			}
+			select {
+			case <-req.Context().Done():
+				return res, req.Context().Err()
+			case <-time.After(cc.retryDelay(attempt, res)):
+			}
+		}
+		res, err = cc.doOnce(req, v)
+		if err == nil && !isRetryableStatus(res.StatusCode) {
+			return res, nil
+		}
+		if attempt >= cc.retryPolicy.MaxRetries {
+			return res, err
+		}
+		if cc.logger != nil {
+			cc.logger.Printf("camcog: retrying %s %s (attempt %d/%d): %v", req.Method, req.URL.Path, attempt+1, cc.retryPolicy.MaxRetries, retryReason(res, err))
+		}
+	}
+}
+
+func (cc *Camcog) doOnce(req *http.Request, v interface{}) (*http.Response, error) {
+	resp, err := cc.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 300 {
+		err = json.NewDecoder(resp.Body).Decode(v)
+	} else {
+		io.Copy(io.Discard, resp.Body)
+	}
+	return resp, err
+}
@@ -0,0 +1,123 @@
+package camcog
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// Logger is the minimal logging interface used by Camcog to report retried requests.
+// *log.Logger satisfies this interface.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// RetryPolicy configures bounded exponential-backoff retries for requests that fail
+// with a 429 or 5xx response, or a network error. GET requests are always eligible;
+// POST requests are only retried when the caller supplied an idempotency key via
+// WithIdempotencyKey, so that a retry after a network blip cannot create duplicate
+// subjects or access codes.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries attempted after the initial request. Zero
+	// (the default RetryPolicy{}) disables retries entirely.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry, doubling on each subsequent attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff delay, before jitter is applied.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is a sensible bounded backoff suitable for most deployments.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  250 * time.Millisecond,
+	MaxDelay:   5 * time.Second,
+}
+
+func (p RetryPolicy) enabled() bool {
+	return p.MaxRetries > 0
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || (code >= 500 && code < 600)
+}
+
+// retryDelay computes how long to wait before the given retry attempt (1-based),
+// preferring the Retry-After header of the previous response when present.
+func (cc *Camcog) retryDelay(attempt int, prevRes *http.Response) time.Duration {
+	if prevRes != nil {
+		if d, ok := retryAfterDelay(prevRes); ok {
+			return d
+		}
+	}
+	d := cc.retryPolicy.BaseDelay * time.Duration(math.Pow(2, float64(attempt-1)))
+	if d <= 0 {
+		return 0
+	}
+	if d > cc.retryPolicy.MaxDelay {
+		d = cc.retryPolicy.MaxDelay
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+func retryAfterDelay(res *http.Response) (time.Duration, bool) {
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if when, err := http.ParseTime(v); err == nil {
+		return time.Until(when), true
+	}
+	return 0, false
+}
+
+func retryReason(res *http.Response, err error) interface{} {
+	if err != nil {
+		return err
+	}
+	return res.Status
+}
+
+// requestConfig holds per-call overrides applied via RequestOption.
+type requestConfig struct {
+	idempotencyKey string
+	timeout        time.Duration
+}
+
+func newRequestConfig(opts []RequestOption) *requestConfig {
+	rc := &requestConfig{}
+	for _, opt := range opts {
+		opt(rc)
+	}
+	return rc
+}
+
+// apply sets the headers on req implied by rc.
+func (rc *requestConfig) apply(req *http.Request) {
+	if rc.idempotencyKey != "" {
+		req.Header.Set(idempotencyKeyHeader, rc.idempotencyKey)
+	}
+}
+
+// RequestOption overrides per-call behaviour such as timeout or idempotency key,
+// without mutating the client the call is made on.
+type RequestOption func(*requestConfig)
+
+// WithIdempotencyKey attaches a caller-supplied idempotency key to a single createSubject
+// or GenerateSubjectAccessCode call, making it safe to retry after a network blip without
+// risking duplicate creation server-side.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(rc *requestConfig) { rc.idempotencyKey = key }
+}
+
+// WithRequestTimeout overrides the client's configured timeout for a single call.
+func WithRequestTimeout(d time.Duration) RequestOption {
+	return func(rc *requestConfig) { rc.timeout = d }
+}
@@ -0,0 +1,61 @@
+package camcog
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIsRetryableStatus(t *testing.T) {
+	cases := []struct {
+		code int
+		want bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusNotFound, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+	}
+	for _, c := range cases {
+		if got := isRetryableStatus(c.code); got != c.want {
+			t.Errorf("isRetryableStatus(%d) = %v, want %v", c.code, got, c.want)
+		}
+	}
+}
+
+func TestRetryDelayBoundedByMaxDelay(t *testing.T) {
+	cc, err := NewCamcog("https://example.com", "", "", "", WithRetryPolicy(RetryPolicy{
+		MaxRetries: 5,
+		BaseDelay:  100 * time.Millisecond,
+		MaxDelay:   time.Second,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for attempt := 1; attempt <= 5; attempt++ {
+		d := cc.retryDelay(attempt, nil)
+		if d < 0 || d > cc.retryPolicy.MaxDelay {
+			t.Errorf("retryDelay(%d) = %v, want within [0, %v]", attempt, d, cc.retryPolicy.MaxDelay)
+		}
+	}
+}
+
+func TestRetryDelayHonoursRetryAfterSeconds(t *testing.T) {
+	cc, err := NewCamcog("https://example.com", "", "", "", WithRetryPolicy(DefaultRetryPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+	if got, want := cc.retryDelay(1, res), 2*time.Second; got != want {
+		t.Errorf("retryDelay with Retry-After: 2 = %v, want %v", got, want)
+	}
+}
+
+func TestRetryAfterDelayIgnoresUnparseableHeader(t *testing.T) {
+	res := &http.Response{Header: http.Header{"Retry-After": []string{"not-a-valid-value"}}}
+	if _, ok := retryAfterDelay(res); ok {
+		t.Error("expected ok=false for an unparseable Retry-After header")
+	}
+}
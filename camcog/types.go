@@ -0,0 +1,184 @@
+package camcog
+
+// CreateSubjectRequest is used to generate a new subject
+type CreateSubjectRequest struct {
+	SubjectIds   []string `json:"subjectIds"`
+	GroupDef     string   `json:"groupDef"`
+	Site         string   `json:"site"`
+	Study        string   `json:"study"`
+	StudyDef     string   `json:"studyDef"`
+	Organisation string   `json:"organisation"`
+	Status       string   `json:"status"`
+}
+
+// SubjectsResponse is returned from API endpoints
+// https://connect-demo.int.cantab.com/api/subject    (GET)
+// https://connect-demo.int.cantab.com/server-webservices/subject   (POST)
+// Generated using https://mholt.github.io/json-to-go/
+type SubjectsResponse struct {
+	Records []struct {
+		ClientID        interface{}   `json:"clientId"`
+		GroupDef        string        `json:"groupDef"`
+		Locale          interface{}   `json:"locale"`
+		Organisation    string        `json:"organisation"`
+		ReplacedBy      interface{}   `json:"replacedBy"`
+		Replicas        []interface{} `json:"replicas"`
+		ScreeningStatus interface{}   `json:"screeningStatus"`
+		Site            string        `json:"site"`
+		Status          string        `json:"status"`
+		Study           string        `json:"study"`
+		StudyDef        string        `json:"studyDef"`
+		SubjectIds      []string      `json:"subjectIds"`
+		SubjectItems    []struct {
+			ClientID       interface{} `json:"clientId"`
+			SubjectItemDef string      `json:"subjectItemDef"`
+			ID             string      `json:"id"`
+			Text           interface{} `json:"text"`
+			MultiText      interface{} `json:"multiText"`
+			Date           interface{} `json:"date"`
+			Integer        interface{} `json:"integer"`
+			Locale         string      `json:"locale"`
+			HidesPII       bool        `json:"hidesPII"`
+		} `json:"subjectItems"`
+		ID      string `json:"id"`
+		Version int    `json:"version"`
+	} `json:"records"`
+	Total   int  `json:"total"`
+	Success bool `json:"success"`
+}
+
+// SubjectLoginInfo provides login information for a given patient
+type SubjectLoginInfo struct {
+	Records []struct {
+		AccessCode               string      `json:"accessCode"`
+		AccessCodeCreationDate   int64       `json:"accessCodeCreationDate"`
+		AccessCodeCreatorID      interface{} `json:"accessCodeCreatorId"`
+		AccessCodeCreatorType    interface{} `json:"accessCodeCreatorType"`
+		ClientID                 interface{} `json:"clientId"`
+		LastSubjectLinkEmailSent interface{} `json:"lastSubjectLinkEmailSent"`
+		Organisation             string      `json:"organisation"`
+		Site                     string      `json:"site"`
+		Study                    string      `json:"study"`
+		Subject                  string      `json:"subject"`
+		ID                       string      `json:"id"`
+		Version                  int         `json:"version"`
+	} `json:"records"`
+	Total   int  `json:"total"`
+	Success bool `json:"success"`
+}
+
+// StudyDefinitionResponse is returned from API endpoint https://connect-demo.int.cantab.com/api/studyDef
+type StudyDefinitionResponse struct {
+	Records []struct {
+		ClientID        string        `json:"clientId"`
+		DataEnrichments []interface{} `json:"dataEnrichments"`
+		GroupDefs       []struct {
+			AllocationParameters []struct {
+				ClientID   interface{} `json:"clientId"`
+				Method     string      `json:"method"`
+				StimuliSet string      `json:"stimuliSet"`
+				TestCode   string      `json:"testCode"`
+				ID         string      `json:"id"`
+			} `json:"allocationParameters"`
+			ClientID  string `json:"clientId"`
+			Name      string `json:"name"`
+			VisitDefs []struct {
+				CanBeSelfAdministered   interface{}   `json:"canBeSelfAdministered"`
+				ClientID                string        `json:"clientId"`
+				ConditionalReleaseTexts []interface{} `json:"conditionalReleaseTexts"`
+				Description             string        `json:"description"`
+				ItemGroupDefs           []struct {
+					ClientID           string      `json:"clientId"`
+					FirstPeerTestDefID interface{} `json:"firstPeerTestDefId"`
+					Mode               string      `json:"mode"`
+					Precondition       interface{} `json:"precondition"`
+					PreconditionAction interface{} `json:"preconditionAction"`
+					TestCode           string      `json:"testCode"`
+					TestExecutionDefID interface{} `json:"testExecutionDefId"`
+					ID                 string      `json:"id"`
+				} `json:"itemGroupDefs"`
+				Name                      string      `json:"name"`
+				Optional                  bool        `json:"optional"`
+				RequiredSubjectIdentifier interface{} `json:"requiredSubjectIdentifier"`
+				UpdateSubjectStatusTo     interface{} `json:"updateSubjectStatusTo"`
+				VisitID                   string      `json:"visitId"`
+				ID                        string      `json:"id"`
+			} `json:"visitDefs"`
+			ID string `json:"id"`
+		} `json:"groupDefs"`
+		Organisation               string      `json:"organisation"`
+		ParentStudyDef             interface{} `json:"parentStudyDef"`
+		PerformanceObservationsDef struct {
+			ClientID interface{} `json:"clientId"`
+			Enabled  bool        `json:"enabled"`
+			ID       string      `json:"id"`
+		} `json:"performanceObservationsDef"`
+		SelfAdministrationDef struct {
+			AutoCreateSubjectLogins                   bool     `json:"autoCreateSubjectLogins"`
+			ClientID                                  string   `json:"clientId"`
+			ConsentText                               string   `json:"consentText"`
+			EditDetails                               bool     `json:"editDetails"`
+			PermitAllTasks                            bool     `json:"permitAllTasks"`
+			PermittedDevices                          []string `json:"permittedDevices"`
+			ReleaseText                               string   `json:"releaseText"`
+			SelfRegistrationEnabled                   bool     `json:"selfRegistrationEnabled"`
+			ShowConsentMessageToPreRegisteredSubjects bool     `json:"showConsentMessageToPreRegisteredSubjects"`
+			ID                                        string   `json:"id"`
+		} `json:"selfAdministrationDef"`
+		SequenceNumber int    `json:"sequenceNumber"`
+		Status         string `json:"status"`
+		Study          string `json:"study"`
+		SubjectDataDef struct {
+			ClientID              interface{} `json:"clientId"`
+			SubjectIdentifierDefs []struct {
+				ClientID string      `json:"clientId"`
+				Format   string      `json:"format"`
+				HelpText string      `json:"helpText"`
+				Label    string      `json:"label"`
+				Prefix   interface{} `json:"prefix"`
+				ID       string      `json:"id"`
+			} `json:"subjectIdentifierDefs"`
+			SubjectItemDefs []struct {
+				ClientID interface{} `json:"clientId"`
+				HelpText string      `json:"helpText"`
+				ItemSpec struct {
+					ClientID interface{} `json:"clientId"`
+					Locales  []string    `json:"locales"`
+					ID       string      `json:"id"`
+				} `json:"itemSpec"`
+				Label               string      `json:"label"`
+				PatientIdentifiable interface{} `json:"patientIdentifiable"`
+				RequireConfirmation bool        `json:"requireConfirmation"`
+				Required            bool        `json:"required"`
+				Type                string      `json:"type"`
+				ID                  string      `json:"id"`
+			} `json:"subjectItemDefs"`
+			ID string `json:"id"`
+		} `json:"subjectDataDef"`
+		Terminology struct {
+			ClientID interface{} `json:"clientId"`
+			Group    string      `json:"group"`
+			Site     string      `json:"site"`
+			Study    string      `json:"study"`
+			Subject  string      `json:"subject"`
+			Visit    string      `json:"visit"`
+			ID       string      `json:"id"`
+		} `json:"terminology"`
+		ValidationWarnings []struct {
+			ClientID   interface{} `json:"clientId"`
+			TestCode   string      `json:"testCode"`
+			TestDef    string      `json:"testDef"`
+			WarningKey string      `json:"warningKey"`
+			ID         string      `json:"id"`
+			Type       string      `json:"type"`
+		} `json:"validationWarnings"`
+		VersionName           string        `json:"versionName"`
+		ID                    string        `json:"id"`
+		Version               int           `json:"version"`
+		CreationDateTime      int64         `json:"creationDateTime"`
+		ReasonForCreation     string        `json:"reasonForCreation"`
+		DataEnrichmentPalette []interface{} `json:"dataEnrichmentPalette"`
+	} `json:"records"`
+	Total   int  `json:"total"`
+	Success bool `json:"success"`
+}
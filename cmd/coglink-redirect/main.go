@@ -0,0 +1,130 @@
+// Command coglink-redirect is a small HTTP service that redirects a subject
+// identifier straight to their CANTAB questionnaire URL. It is intended to be
+// dropped behind a front-end web server as the "custom web service that
+// simply redirects to the questionnaire" described in the camcog package.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/spf13/viper"
+	"github.com/wardle/coglink/camcog"
+)
+
+func main() {
+	viper.SetEnvPrefix("camcog")
+	viper.AutomaticEnv()
+	viper.SetDefault("UserAgent", camcog.DefaultUserAgent)
+	viper.SetDefault("listen_socket_mode", "0660")
+	viper.SetConfigName("config")
+	viper.AddConfigPath("/etc/appname/")
+	viper.AddConfigPath("$HOME/.camcog")
+	viper.AddConfigPath(".")
+	if err := viper.ReadInConfig(); err != nil {
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
+	cc, err := camcog.NewCamcog(
+		viper.GetString("baseURL"),
+		viper.GetString("username"),
+		viper.GetString("password"),
+		viper.GetString("userAgent"),
+		camcog.WithTimeout(viper.GetDuration("request_timeout")),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := &server{
+		cc: cc,
+		cfg: studyConfig{
+			StudyID: viper.GetString("studyID"),
+		},
+	}
+	addr := viper.GetString("listen_addr")
+	socketPath := viper.GetString("listen_socket")
+	if addr == "" && socketPath == "" {
+		addr = ":8080"
+	}
+	log.Fatal(listenAndServe(srv.routes(), addr, socketPath, viper.GetString("listen_socket_mode")))
+}
+
+// studyConfig holds the per-server study configuration needed to look up a subject,
+// read once from configuration rather than from globals.
+type studyConfig struct {
+	StudyID string
+}
+
+// server holds the dependencies shared by the HTTP handlers.
+type server struct {
+	cc  *camcog.Camcog
+	cfg studyConfig
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/redirect/", s.handleRedirect)
+	mux.HandleFunc("/url/", s.handleURL)
+	return mux
+}
+
+// subjectURL looks up subjectID's existing questionnaire URL. This service is
+// unauthenticated and internet-facing, so it must never be able to register a new
+// subject on behalf of an anonymous caller: it uses LookupSubject, which reports
+// camcog.ErrSubjectNotFound rather than falling through to subject creation.
+func (s *server) subjectURL(ctx context.Context, subjectID string) (string, error) {
+	sr, err := s.cc.LookupSubject(ctx, s.cfg.StudyID, subjectID)
+	if err != nil {
+		return "", err
+	}
+	subject := sr.Records[0].ID
+	sli, err := s.cc.GenerateSubjectAccessCode(ctx, subject)
+	if err != nil {
+		return "", err
+	}
+	if len(sli.Records) != 1 {
+		return "", fmt.Errorf("did not get access code for subject %s", subjectID)
+	}
+	return s.cc.GenerateURL(subject, sli.Records[0].AccessCode), nil
+}
+
+// handleRedirect issues a 302 redirect from /redirect/{subjectID} to the CANTAB questionnaire URL.
+func (s *server) handleRedirect(w http.ResponseWriter, r *http.Request) {
+	subjectID := r.URL.Path[len("/redirect/"):]
+	if subjectID == "" {
+		http.NotFound(w, r)
+		return
+	}
+	url, err := s.subjectURL(r.Context(), subjectID)
+	if errors.Is(err, camcog.ErrSubjectNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// handleURL returns the CANTAB questionnaire URL for /url/{subjectID} as JSON, without redirecting.
+func (s *server) handleURL(w http.ResponseWriter, r *http.Request) {
+	subjectID := r.URL.Path[len("/url/"):]
+	if subjectID == "" {
+		http.NotFound(w, r)
+		return
+	}
+	url, err := s.subjectURL(r.Context(), subjectID)
+	if errors.Is(err, camcog.ErrSubjectNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, "{\"subjectId\":%q,\"url\":%q}\n", subjectID, url)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// listenAndServe serves handler on addr (a TCP address, e.g. ":8080") and/or socketPath
+// (a Unix domain socket path), whichever are non-empty, so the service can be put behind
+// an nginx/Apache reverse proxy running as a different user without exposing a TCP port.
+// If both are set, both are served concurrently; listenAndServe returns once either fails.
+func listenAndServe(handler http.Handler, addr string, socketPath string, socketMode string) error {
+	errCh := make(chan error, 2)
+	listeners := 0
+
+	if socketPath != "" {
+		l, err := listenUnix(socketPath, socketMode)
+		if err != nil {
+			return err
+		}
+		listeners++
+		log.Printf("listening on unix socket %s", socketPath)
+		go func() { errCh <- http.Serve(l, handler) }()
+	}
+	if addr != "" {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return err
+		}
+		listeners++
+		log.Printf("listening on %s", addr)
+		go func() { errCh <- http.Serve(l, handler) }()
+	}
+	if listeners == 0 {
+		return fmt.Errorf("no listener configured: set listen_addr or listen_socket")
+	}
+	return <-errCh
+}
+
+// listenUnix creates a Unix domain socket listener at path, removing any stale socket
+// file left behind by a previous run and chmod-ing the new one to mode (an octal string,
+// e.g. "0660") so a reverse proxy running as a different user can connect to it.
+func listenUnix(path string, mode string) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	perm, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		l.Close()
+		return nil, fmt.Errorf("invalid listen_socket_mode %q: %w", mode, err)
+	}
+	if err := os.Chmod(path, os.FileMode(perm)); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/wardle/coglink/camcog"
+	"github.com/wardle/coglink/notify"
+)
+
+// batchOptions controls concurrent processing of a CSV of subject identifiers.
+type batchOptions struct {
+	Concurrency int
+	OutputFile  string
+	ErrorsFile  string
+	Resume      bool
+	Notifier    notify.Notifier
+}
+
+var outputHeader = []string{"id", "email", "url", "accessCode", "subjectUUID"}
+var errorsHeader = []string{"id", "email", "http_status", "error"}
+
+// processCsv streams id,email rows from filename and processes them concurrently,
+// writing successful rows to opts.OutputFile and failed rows to opts.ErrorsFile.
+// A per-row error (such as a CAMCOG 4xx response) is recorded in the errors file and
+// does not stop the batch; only a configuration or authentication failure aborts it.
+func processCsv(cc *camcog.Camcog, cfg studyConfig, filename string, opts batchOptions) error {
+	in, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	alreadyDone := map[string]bool{}
+	if opts.Resume {
+		alreadyDone, err = readProcessedIDs(opts.OutputFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	outW, closeOut, err := newResultsWriter(opts.OutputFile, opts.Resume, outputHeader)
+	if err != nil {
+		return err
+	}
+	defer closeOut()
+	errW, closeErr, err := newResultsWriter(opts.ErrorsFile, opts.Resume, errorsHeader)
+	if err != nil {
+		return err
+	}
+	defer closeErr()
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	notifier := opts.Notifier
+	if notifier == nil {
+		notifier = notify.NoopNotifier{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type csvRow struct{ id, email string }
+	type outcome struct {
+		result *subjectResult
+		row    csvRow
+		rowErr error
+	}
+	rows := make(chan csvRow)
+	outcomes := make(chan outcome)
+
+	var fatalMu sync.Mutex
+	var fatalErr error
+	setFatal := func(err error) {
+		fatalMu.Lock()
+		defer fatalMu.Unlock()
+		if fatalErr == nil {
+			fatalErr = err
+			cancel()
+		}
+	}
+
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for row := range rows {
+				res, err := processSingleSubject(ctx, cc, cfg, row.id)
+				if err != nil && isFatalError(err) {
+					setFatal(err)
+				}
+				if err != nil {
+					outcomes <- outcome{row: row, rowErr: err}
+					continue
+				}
+				if err := notifier.Send(ctx, row.email, row.id, res.URL); err != nil {
+					outcomes <- outcome{row: row, rowErr: fmt.Errorf("notify: %w", err)}
+					continue
+				}
+				outcomes <- outcome{row: row, result: &res}
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(outcomes)
+	}()
+
+	reader := csv.NewReader(in)
+	// Allow rows with a different field count than the first row, so that a single
+	// malformed line can be routed to the errors file as a per-row error instead of
+	// reader.Read() returning csv.ErrFieldCount and aborting the whole batch.
+	reader.FieldsPerRecord = -1
+	go func() {
+		defer close(rows)
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				setFatal(err)
+				return
+			}
+			if len(record) < 2 {
+				outcomes <- outcome{row: csvRow{id: strings.Join(record, ",")}, rowErr: fmt.Errorf("malformed row: expected at least 2 fields, got %d", len(record))}
+				continue
+			}
+			row := csvRow{id: record[0], email: record[1]}
+			if alreadyDone[row.id] {
+				continue
+			}
+			select {
+			case rows <- row:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for o := range outcomes {
+		if o.rowErr != nil {
+			errW.Write([]string{o.row.id, o.row.email, strconv.Itoa(statusCode(o.rowErr)), o.rowErr.Error()})
+			errW.Flush()
+			continue
+		}
+		outW.Write([]string{o.row.id, o.row.email, o.result.URL, o.result.AccessCode, o.result.SubjectUUID})
+		outW.Flush()
+	}
+
+	return fatalErr
+}
+
+// isFatalError reports whether err represents a configuration or authentication
+// failure that should abort the whole batch, rather than just the current row.
+func isFatalError(err error) bool {
+	var se *camcog.StatusError
+	if errors.As(err, &se) {
+		return se.Code == http.StatusUnauthorized || se.Code == http.StatusForbidden
+	}
+	return false
+}
+
+// statusCode extracts the remote HTTP status code from err, or 0 if it did not
+// originate from a camcog.StatusError.
+func statusCode(err error) int {
+	var se *camcog.StatusError
+	if errors.As(err, &se) {
+		return se.Code
+	}
+	return 0
+}
+
+// readProcessedIDs reads the id column of an existing output CSV, returning the set
+// of ids already processed so that -resume can skip them. A missing file is not an error.
+func readProcessedIDs(filename string) (map[string]bool, error) {
+	done := map[string]bool{}
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	if _, err := r.Read(); err == io.EOF { // skip the header row
+		return done, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return done, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) > 0 {
+			done[record[0]] = true
+		}
+	}
+}
+
+// newResultsWriter opens filename for writing results, appending to an existing file
+// (without rewriting its header) when resume is true and the file already exists.
+// It returns a flush-on-close function that must be called once writing is complete.
+func newResultsWriter(filename string, resume bool, header []string) (*csv.Writer, func() error, error) {
+	_, statErr := os.Stat(filename)
+	appending := resume && statErr == nil
+	flags := os.O_CREATE | os.O_WRONLY
+	if appending {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(filename, flags, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	w := csv.NewWriter(f)
+	if !appending {
+		if err := w.Write(header); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		w.Flush()
+	}
+	return w, func() error {
+		w.Flush()
+		if err := w.Error(); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}, nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wardle/coglink/camcog"
+)
+
+func TestReadProcessedIDsMissingFile(t *testing.T) {
+	done, err := readProcessedIDs(filepath.Join(t.TempDir(), "does-not-exist.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 0 {
+		t.Errorf("expected an empty set for a missing file, got %v", done)
+	}
+}
+
+func TestReadProcessedIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.csv")
+	content := "id,email,url,accessCode,subjectUUID\nabc,a@example.com,https://x,code,uuid\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	done, err := readProcessedIDs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done["abc"] {
+		t.Errorf("expected %q to be marked done, got %v", "abc", done)
+	}
+	if len(done) != 1 {
+		t.Errorf("expected exactly one processed id, got %v", done)
+	}
+}
+
+func TestNewResultsWriterResumeAppendsWithoutRewritingHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.csv")
+	header := []string{"id", "email"}
+
+	w, closeFn, err := newResultsWriter(path, false, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]string{"1", "a@example.com"})
+	if err := closeFn(); err != nil {
+		t.Fatal(err)
+	}
+
+	w, closeFn, err = newResultsWriter(path, true, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]string{"2", "b@example.com"})
+	if err := closeFn(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "id,email\n1,a@example.com\n2,b@example.com\n"
+	if string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewResultsWriterNoResumeTruncatesExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.csv")
+	if err := os.WriteFile(path, []byte("stale content\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, closeFn, err := newResultsWriter(path, false, []string{"id", "email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := closeFn(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "id,email\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// newFakeCamcogServer returns a test CAMCOG server that always registers a new
+// subject and issues an access code for it, regardless of the identifier supplied.
+func newFakeCamcogServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/subject", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"records":[],"total":0,"success":true}`)
+	})
+	mux.HandleFunc("/server-webservices/subject", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"records":[{"id":"uuid-1"}],"total":1,"success":true}`)
+	})
+	mux.HandleFunc("/server-webservices/subjectLoginInfo", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"records":[{"accessCode":"code-1"}],"total":1,"success":true}`)
+	})
+	return httptest.NewServer(mux)
+}
+
+// TestProcessCsvSkipsMalformedRowsWithoutAbortingBatch is a regression test for a bug
+// where csv.Reader's default FieldsPerRecord (set from the first row) meant a later row
+// with a different field count made reader.Read() itself return csv.ErrFieldCount, which
+// was treated as fatal and aborted the whole batch instead of just that row.
+func TestProcessCsvSkipsMalformedRowsWithoutAbortingBatch(t *testing.T) {
+	srv := newFakeCamcogServer()
+	defer srv.Close()
+
+	cc, err := camcog.NewCamcog(srv.URL, "user", "pass", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "subjects.csv")
+	content := "id1,a@example.com\nid2\nid3,c@example.com\n"
+	if err := os.WriteFile(csvPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := studyConfig{GroupDef: "g", Organisation: "o", StudyID: "s", Site: "site", StudyDef: "sd"}
+	opts := batchOptions{
+		Concurrency: 2,
+		OutputFile:  filepath.Join(dir, "output.csv"),
+		ErrorsFile:  filepath.Join(dir, "errors.csv"),
+	}
+
+	if err := processCsv(cc, cfg, csvPath, opts); err != nil {
+		t.Fatalf("processCsv returned a fatal error, want the batch to keep going: %v", err)
+	}
+
+	out, err := os.ReadFile(opts.OutputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(strings.Split(strings.TrimRight(string(out), "\n"), "\n")), 3; got != want {
+		t.Errorf("output.csv has %d lines, want %d (header + id1 + id3): %q", got, want, out)
+	}
+
+	errs, err := os.ReadFile(opts.ErrorsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(errs), "id2") {
+		t.Errorf("errors.csv does not record the malformed row: %q", errs)
+	}
+	if got, want := len(strings.Split(strings.TrimRight(string(errs), "\n"), "\n")), 2; got != want {
+		t.Errorf("errors.csv has %d lines, want %d (header + id2): %q", got, want, errs)
+	}
+}
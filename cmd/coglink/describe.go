@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/wardle/coglink/camcog"
+)
+
+// describeStudy fetches and prints the study definition for studyID, letting an
+// operator validate their config.yml values against what the remote CAMCOG server
+// actually accepts before trying to register subjects.
+func describeStudy(ctx context.Context, cc *camcog.Camcog, studyID string, jsonMode bool) error {
+	sd, err := cc.GetStudyDefinition(ctx, studyID)
+	if err != nil {
+		return err
+	}
+	if jsonMode {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sd)
+	}
+	if len(sd.Records) == 0 {
+		return fmt.Errorf("no study definition found for study %s", studyID)
+	}
+	printStudyDefinition(os.Stdout, sd)
+	return nil
+}
+
+// printStudyDefinition prints a human-readable summary of the first study definition
+// record in sd: study name, group and visit defs, subject identifier formats, and
+// self-administration settings.
+func printStudyDefinition(w io.Writer, sd *camcog.StudyDefinitionResponse) {
+	rec := sd.Records[0]
+	fmt.Fprintf(w, "Study:   %s\n", rec.Study)
+	fmt.Fprintf(w, "Version: %s\n", rec.VersionName)
+	fmt.Fprintf(w, "Status:  %s\n", rec.Status)
+	fmt.Fprintln(w, "Group definitions:")
+	for _, gd := range rec.GroupDefs {
+		fmt.Fprintf(w, "  - %s\n", gd.Name)
+		for _, vd := range gd.VisitDefs {
+			fmt.Fprintf(w, "      visit %s: %s (optional=%v)\n", vd.VisitID, vd.Name, vd.Optional)
+		}
+	}
+	fmt.Fprintln(w, "Subject identifier formats:")
+	for _, sid := range rec.SubjectDataDef.SubjectIdentifierDefs {
+		fmt.Fprintf(w, "  - %s: format=%s\n", sid.Label, sid.Format)
+	}
+	sa := rec.SelfAdministrationDef
+	fmt.Fprintln(w, "Self-administration:")
+	fmt.Fprintf(w, "  self-registration=%v  auto-create logins=%v  permitted devices=%v\n",
+		sa.SelfRegistrationEnabled, sa.AutoCreateSubjectLogins, sa.PermittedDevices)
+}
@@ -0,0 +1,166 @@
+// Command coglink is a command-line tool that generates a unique URL for a set
+// of cognitive questionnaires using the CAMCOG web service, taking in a single
+// subject identifier or processing a list of identifiers from a CSV file.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/wardle/coglink/camcog"
+)
+
+const version = 0.1
+
+var flagConfig = flag.String("config", "config.yml", "Location of configuration file. Default config.yml in current directory, /etc/ or ~/.camcog/")
+var flagPassword = flag.String("password", "", "password")
+var flagSubject = flag.String("subject", "", "local subject identifier")
+var flagProcess = flag.String("csv", "", "Process a CSV containing identifiers in the first column")
+var flagVersion = flag.Bool("version", false, "Prints version information")
+var flagConcurrency = flag.Int("concurrency", 4, "Number of subjects to process concurrently when using -csv")
+var flagResume = flag.Bool("resume", false, "Resume a previous -csv run, skipping ids already present in -output")
+var flagOutput = flag.String("output", "output.csv", "Output CSV file for successfully processed rows, used with -csv")
+var flagErrors = flag.String("errors", "errors.csv", "Output CSV file for rows that failed to process, used with -csv")
+var flagDescribeStudy = flag.Bool("describe-study", false, "Print a summary of the study definition for the configured studyID and exit")
+var flagJSON = flag.Bool("json", false, "With -describe-study, dump the raw study definition response as JSON")
+var flagDryRun = flag.Bool("dry-run", false, "With -csv, render participant notifications to stdout instead of sending them")
+
+// studyConfig holds the per-run study configuration needed to register or
+// fetch a subject. It is read once from configuration, rather than looked
+// up from package-level globals inside the processing functions.
+type studyConfig struct {
+	GroupDef     string
+	Organisation string
+	StudyID      string
+	Site         string
+	StudyDef     string
+}
+
+func main() {
+	// bring in command-line flags into our configuration
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+	viper.BindPFlags(pflag.CommandLine)
+	// configure config files and environmental variables
+	viper.SetEnvPrefix("camcog") // will be uppercased automatically
+	viper.AutomaticEnv()
+	viper.SetDefault("UserAgent", camcog.DefaultUserAgent)
+	viper.SetDefault("request_timeout", 30*time.Second)
+	viper.SetDefault("retry.max_retries", camcog.DefaultRetryPolicy.MaxRetries)
+	viper.SetDefault("retry.base_delay", camcog.DefaultRetryPolicy.BaseDelay)
+	viper.SetDefault("retry.max_delay", camcog.DefaultRetryPolicy.MaxDelay)
+	viper.SetConfigName("config")
+	viper.AddConfigPath("/etc/appname/") // path to look for the config file in
+	viper.AddConfigPath("$HOME/.camcog") // call multiple times to add many search paths
+	viper.AddConfigPath(".")             // optionally look for config in the working directory
+	if *flagConfig != "" {
+		viper.AddConfigPath(*flagConfig)
+	}
+	err := viper.ReadInConfig() // Find and read the config file
+	if err != nil {             // Handle errors reading the config file
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
+	cc, err := camcog.NewCamcog(
+		viper.GetString("baseURL"),
+		viper.GetString("username"),
+		viper.GetString("password"),
+		viper.GetString("userAgent"),
+		// Bound every request so that a hung CANTAB backend cannot block the CLI
+		// forever, and retry transient failures with bounded backoff.
+		camcog.WithTimeout(viper.GetDuration("request_timeout")),
+		camcog.WithRetryPolicy(camcog.RetryPolicy{
+			MaxRetries: viper.GetInt("retry.max_retries"),
+			BaseDelay:  viper.GetDuration("retry.base_delay"),
+			MaxDelay:   viper.GetDuration("retry.max_delay"),
+		}),
+		camcog.WithLogger(log.Default()),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg := studyConfig{
+		GroupDef:     viper.GetString("groupDef"),
+		Organisation: viper.GetString("organisation"),
+		StudyID:      viper.GetString("studyID"),
+		Site:         viper.GetString("site"),
+		StudyDef:     viper.GetString("studyDef"),
+	}
+	if *flagVersion {
+		fmt.Printf("camcog URL generator V%v\n", version)
+		os.Exit(0)
+	}
+	if *flagDescribeStudy {
+		if err := describeStudy(context.Background(), cc, cfg.StudyID, *flagJSON); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}
+	if *flagSubject != "" {
+		res, err := processSingleSubject(context.Background(), cc, cfg, *flagSubject)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(res.URL)
+		os.Exit(0)
+	}
+	if *flagProcess != "" {
+		notifier, err := buildNotifier()
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts := batchOptions{
+			Concurrency: *flagConcurrency,
+			OutputFile:  *flagOutput,
+			ErrorsFile:  *flagErrors,
+			Resume:      *flagResume,
+			Notifier:    notifier,
+		}
+		if err := processCsv(cc, cfg, *flagProcess, opts); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}
+	flag.PrintDefaults()
+	os.Exit(1)
+}
+
+// subjectResult is the outcome of successfully generating a subject's questionnaire URL.
+type subjectResult struct {
+	URL         string
+	AccessCode  string
+	SubjectUUID string
+}
+
+func processSingleSubject(ctx context.Context, cc *camcog.Camcog, cfg studyConfig, subject string) (subjectResult, error) {
+	// Idempotency keys are derived deterministically from the subject identifier so
+	// that retrying the same subject after a network blip cannot create a duplicate
+	// subject or access code server-side.
+	sr, err := cc.GetSubject(ctx, cfg.GroupDef, cfg.Organisation, cfg.StudyID, cfg.Site, cfg.StudyDef, subject, camcog.WithIdempotencyKey("create-subject:"+cfg.StudyID+":"+subject))
+	if err != nil {
+		return subjectResult{}, err
+	}
+	if len(sr.Records) != 1 {
+		return subjectResult{}, errors.New("no access code generated from remote service")
+	}
+	subjectUUID := sr.Records[0].ID
+	sli, err := cc.GenerateSubjectAccessCode(ctx, subjectUUID, camcog.WithIdempotencyKey("access-code:"+subjectUUID))
+	if err != nil {
+		return subjectResult{}, err
+	}
+	if len(sli.Records) != 1 {
+		return subjectResult{}, fmt.Errorf("did not get access code for subject %s", subjectUUID)
+	}
+	accessCode := sli.Records[0].AccessCode
+	return subjectResult{
+		URL:         cc.GenerateURL(subjectUUID, accessCode),
+		AccessCode:  accessCode,
+		SubjectUUID: subjectUUID,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"net/smtp"
+	"os"
+	"text/template"
+
+	"github.com/spf13/viper"
+	"github.com/wardle/coglink/notify"
+)
+
+const (
+	defaultSubjectTemplate = "Complete your {{.StudyName}} questionnaires"
+	defaultBodyTemplate    = "Please complete your questionnaires using the link below:\n\n{{.URL}}\n"
+)
+
+// buildNotifier constructs the Notifier configured under the notifier.* keys, used to
+// dispatch each successfully generated URL to its participant. If -dry-run was passed,
+// the configured type is ignored and messages are rendered to stdout instead of sent.
+func buildNotifier() (notify.Notifier, error) {
+	subjectTmpl, err := template.New("subject").Parse(viperStringOrDefault("notifier.subjectTemplate", defaultSubjectTemplate))
+	if err != nil {
+		return nil, fmt.Errorf("invalid notifier.subjectTemplate: %w", err)
+	}
+	bodyTmpl, err := template.New("body").Parse(viperStringOrDefault("notifier.bodyTemplate", defaultBodyTemplate))
+	if err != nil {
+		return nil, fmt.Errorf("invalid notifier.bodyTemplate: %w", err)
+	}
+	studyName := viper.GetString("studyName")
+
+	if *flagDryRun {
+		return notify.NewDryRunNotifier(os.Stdout, studyName, subjectTmpl, bodyTmpl), nil
+	}
+
+	switch viper.GetString("notifier.type") {
+	case "smtp":
+		var auth smtp.Auth
+		if username := viper.GetString("notifier.smtp.username"); username != "" {
+			auth = smtp.PlainAuth("", username, viper.GetString("notifier.smtp.password"), viper.GetString("notifier.smtp.host"))
+		}
+		return notify.NewSMTPNotifier(
+			viper.GetString("notifier.smtp.addr"),
+			auth,
+			viper.GetString("notifier.smtp.from"),
+			studyName,
+			subjectTmpl,
+			bodyTmpl,
+		), nil
+	case "webhook":
+		return notify.NewWebhookNotifier(
+			viper.GetString("notifier.webhook.endpoint"),
+			[]byte(viper.GetString("notifier.webhook.secret")),
+			nil,
+		), nil
+	default:
+		return notify.NoopNotifier{}, nil
+	}
+}
+
+func viperStringOrDefault(key string, def string) string {
+	if v := viper.GetString(key); v != "" {
+		return v
+	}
+	return def
+}
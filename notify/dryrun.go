@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/template"
+)
+
+// DryRunNotifier renders the configured message templates and writes them to out
+// instead of sending anything, so an operator can preview invitations before
+// running a batch for real.
+type DryRunNotifier struct {
+	out         io.Writer
+	studyName   string
+	subjectTmpl *template.Template
+	bodyTmpl    *template.Template
+}
+
+// NewDryRunNotifier creates a Notifier that previews messages on out.
+func NewDryRunNotifier(out io.Writer, studyName string, subjectTmpl *template.Template, bodyTmpl *template.Template) *DryRunNotifier {
+	return &DryRunNotifier{out: out, studyName: studyName, subjectTmpl: subjectTmpl, bodyTmpl: bodyTmpl}
+}
+
+// Send implements Notifier by writing the rendered message to out.
+func (n *DryRunNotifier) Send(ctx context.Context, recipient string, subjectID string, url string) error {
+	msg, err := RenderMessage(n.subjectTmpl, n.bodyTmpl, n.studyName, subjectID, url)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(n.out, "--- dry run: would notify %s ---\nSubject: %s\n\n%s\n\n", recipient, msg.Subject, msg.Body)
+	return nil
+}
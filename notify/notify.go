@@ -0,0 +1,63 @@
+// Package notify dispatches a generated questionnaire URL to the participant who
+// should complete it, so that coglink can act as an end-to-end invitation pipeline
+// rather than just a URL printer.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"text/template"
+)
+
+// Notifier sends a participant the URL for their subjectID. Implementations should
+// treat recipient as an email address, webhook target, or other address understood
+// by the underlying transport.
+type Notifier interface {
+	Send(ctx context.Context, recipient string, subjectID string, url string) error
+}
+
+// NoopNotifier is the default Notifier, used when no delivery mechanism is configured.
+// It discards every message without error.
+type NoopNotifier struct{}
+
+// Send implements Notifier by doing nothing.
+func (NoopNotifier) Send(ctx context.Context, recipient string, subjectID string, url string) error {
+	return nil
+}
+
+// messageData supplies the variables available to subject/body templates.
+type messageData struct {
+	SubjectID string
+	URL       string
+	StudyName string
+}
+
+// Message is a rendered subject and body ready for delivery.
+type Message struct {
+	Subject string
+	Body    string
+}
+
+// RenderMessage executes subjectTmpl and bodyTmpl with the given subjectID, url and
+// studyName, as made available to templates via .SubjectID, .URL and .StudyName.
+func RenderMessage(subjectTmpl *template.Template, bodyTmpl *template.Template, studyName string, subjectID string, url string) (Message, error) {
+	data := messageData{SubjectID: subjectID, URL: url, StudyName: studyName}
+	var subject, body bytes.Buffer
+	if err := subjectTmpl.Execute(&subject, data); err != nil {
+		return Message{}, err
+	}
+	if err := bodyTmpl.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+	return Message{Subject: stripCRLF(subject.String()), Body: body.String()}, nil
+}
+
+// stripCRLF removes CR and LF from s. The rendered subject is spliced directly into a
+// raw header block by callers such as SMTPNotifier, and subjectID is attacker-controlled
+// (it comes straight from a CSV column), so a template that embeds it in the subject must
+// not be able to inject additional headers via an embedded newline.
+func stripCRLF(s string) string {
+	s = strings.ReplaceAll(s, "\r", "")
+	return strings.ReplaceAll(s, "\n", "")
+}
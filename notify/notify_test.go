@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestRenderMessage(t *testing.T) {
+	subjectTmpl := template.Must(template.New("subject").Parse("Complete your {{.StudyName}} questionnaires"))
+	bodyTmpl := template.Must(template.New("body").Parse("{{.SubjectID}}: {{.URL}}"))
+
+	msg, err := RenderMessage(subjectTmpl, bodyTmpl, "MyStudy", "subj-1", "https://example.com/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Complete your MyStudy questionnaires"; msg.Subject != want {
+		t.Errorf("Subject = %q, want %q", msg.Subject, want)
+	}
+	if want := "subj-1: https://example.com/x"; msg.Body != want {
+		t.Errorf("Body = %q, want %q", msg.Body, want)
+	}
+}
+
+func TestRenderMessageStripsCRLFFromSubject(t *testing.T) {
+	subjectTmpl := template.Must(template.New("subject").Parse("{{.SubjectID}}"))
+	bodyTmpl := template.Must(template.New("body").Parse("{{.URL}}"))
+
+	msg, err := RenderMessage(subjectTmpl, bodyTmpl, "Study", "evil\r\nBcc: attacker@example.com", "https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		t.Fatalf("Subject still contains CR or LF: %q", msg.Subject)
+	}
+	if want := "evilBcc: attacker@example.com"; msg.Subject != want {
+		t.Errorf("Subject = %q, want %q", msg.Subject, want)
+	}
+}
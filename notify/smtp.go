@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"text/template"
+)
+
+// SMTPNotifier sends the participant invitation by email via an SMTP relay.
+type SMTPNotifier struct {
+	addr        string
+	auth        smtp.Auth
+	from        string
+	studyName   string
+	subjectTmpl *template.Template
+	bodyTmpl    *template.Template
+}
+
+// NewSMTPNotifier creates a Notifier that sends mail via the SMTP server at addr
+// (host:port), authenticating with auth (nil for an open relay) and using from as
+// the envelope and header sender. subjectTmpl and bodyTmpl are rendered per message.
+func NewSMTPNotifier(addr string, auth smtp.Auth, from string, studyName string, subjectTmpl *template.Template, bodyTmpl *template.Template) *SMTPNotifier {
+	return &SMTPNotifier{
+		addr:        addr,
+		auth:        auth,
+		from:        from,
+		studyName:   studyName,
+		subjectTmpl: subjectTmpl,
+		bodyTmpl:    bodyTmpl,
+	}
+}
+
+// Send implements Notifier by rendering the configured templates and relaying the
+// resulting email via SMTP. The net/smtp package has no context support, so ctx is
+// not used.
+func (n *SMTPNotifier) Send(ctx context.Context, recipient string, subjectID string, url string) error {
+	msg, err := RenderMessage(n.subjectTmpl, n.bodyTmpl, n.studyName, subjectID, url)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("To: %s\r\nFrom: %s\r\nSubject: %s\r\n\r\n%s\r\n", recipient, n.from, msg.Subject, msg.Body)
+	return smtp.SendMail(n.addr, n.auth, n.from, []string{recipient}, []byte(body))
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// WebhookNotifier posts the participant invitation as JSON to a configurable endpoint,
+// signing the body with HMAC-SHA256 so the receiver can verify it came from coglink.
+type WebhookNotifier struct {
+	endpoint string
+	secret   []byte
+	client   *http.Client
+}
+
+// NewWebhookNotifier creates a Notifier that POSTs to endpoint. A nil client defaults
+// to http.DefaultClient. An empty secret disables signing.
+func NewWebhookNotifier(endpoint string, secret []byte, client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &WebhookNotifier{endpoint: endpoint, secret: secret, client: client}
+}
+
+type webhookPayload struct {
+	SubjectID string `json:"subjectId"`
+	Email     string `json:"email"`
+	URL       string `json:"url"`
+}
+
+// Send implements Notifier by POSTing {subjectId,email,url} as JSON to the endpoint.
+func (n *WebhookNotifier) Send(ctx context.Context, recipient string, subjectID string, url string) error {
+	body, err := json.Marshal(webhookPayload{SubjectID: subjectID, Email: recipient, URL: url})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", n.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(n.secret) > 0 {
+		mac := hmac.New(sha256.New, n.secret)
+		mac.Write(body)
+		req.Header.Set("X-Coglink-Signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", n.endpoint, res.Status)
+	}
+	return nil
+}